@@ -3,7 +3,10 @@ package main
 import (
 	"bytes"
 	"fmt"
+	"io"
+	"net/http"
 	"testing"
+	"time"
 )
 
 func BenchmarkFmtFprint(b *testing.B) {
@@ -25,3 +28,45 @@ func BenchmarkOutputWrite(b *testing.B) {
 		output.Write([]byte(headers))
 	}
 }
+
+func newBenchmarkServer(addr string) func() {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "ok")
+	})
+	server := &http.Server{Addr: addr, Handler: mux}
+	go server.ListenAndServe()
+	time.Sleep(5 * time.Millisecond)
+	return func() { server.Close() }
+}
+
+// BenchmarkHttpGetPerRequestDial exercises the one-shot HttpGet path, which
+// dials a fresh connection for every request.
+func BenchmarkHttpGetPerRequestDial(b *testing.B) {
+	cleanup := newBenchmarkServer("127.0.0.1:8086")
+	defer cleanup()
+
+	flags := &HttpFlags{}
+	for i := 0; i < b.N; i++ {
+		if err := HttpGet("http://127.0.0.1:8086/", io.Discard, flags); err != nil {
+			b.Fatalf("HttpGet failed: %v", err)
+		}
+	}
+}
+
+// BenchmarkTransportRoundTripPooled exercises Transport.RoundTrip, which
+// reuses a pooled connection across requests to the same host.
+func BenchmarkTransportRoundTripPooled(b *testing.B) {
+	cleanup := newBenchmarkServer("127.0.0.1:8087")
+	defer cleanup()
+
+	transport := &Transport{}
+	defer transport.Close()
+
+	flags := &HttpFlags{}
+	for i := 0; i < b.N; i++ {
+		if _, err := transport.RoundTrip(&Request{Method: "GET", URL: "http://127.0.0.1:8087/", Flags: flags}); err != nil {
+			b.Fatalf("RoundTrip failed: %v", err)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// prepareRequestBody builds the request body described by flags.Data or
+// flags.Form, and records the appropriate Content-Type as a custom header
+// unless the caller already supplied one. It returns a nil body when
+// neither flag is set.
+func prepareRequestBody(flags *HttpFlags) (io.Reader, error) {
+	switch {
+	case len(flags.Form) > 0:
+		body, contentType, err := buildMultipartBody(flags.Form)
+		if err != nil {
+			return nil, err
+		}
+		setDefaultContentType(flags, contentType)
+		return body, nil
+	case flags.Data != "":
+		body, err := buildDataBody(flags.Data)
+		if err != nil {
+			return nil, err
+		}
+		setDefaultContentType(flags, "application/x-www-form-urlencoded")
+		return body, nil
+	default:
+		return nil, nil
+	}
+}
+
+// buildDataBody resolves the -d/--data value, reading from a file when it
+// is prefixed with '@'.
+func buildDataBody(data string) (io.Reader, error) {
+	if strings.HasPrefix(data, "@") {
+		contents, err := os.ReadFile(data[1:])
+		if err != nil {
+			return nil, fmt.Errorf("failed to read data file: %w", err)
+		}
+		return bytes.NewReader(contents), nil
+	}
+	return strings.NewReader(data), nil
+}
+
+// buildMultipartBody encodes fields (each "key=value" or "key=@file") as a
+// multipart/form-data body and returns it along with its Content-Type,
+// including the generated boundary.
+func buildMultipartBody(fields []string) (io.Reader, string, error) {
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+
+	for _, field := range fields {
+		key, value, found := strings.Cut(field, "=")
+		if !found {
+			return nil, "", fmt.Errorf("invalid -F value %q: expected key=value", field)
+		}
+
+		if strings.HasPrefix(value, "@") {
+			if err := writeMultipartFile(writer, key, value[1:]); err != nil {
+				return nil, "", err
+			}
+			continue
+		}
+
+		if err := writer.WriteField(key, value); err != nil {
+			return nil, "", fmt.Errorf("failed to write form field %q: %w", key, err)
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, "", fmt.Errorf("failed to finalize multipart body: %w", err)
+	}
+
+	return &buf, writer.FormDataContentType(), nil
+}
+
+func writeMultipartFile(writer *multipart.Writer, key, filePath string) error {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to open form file %q: %w", filePath, err)
+	}
+	defer file.Close()
+
+	part, err := writer.CreateFormFile(key, filepath.Base(filePath))
+	if err != nil {
+		return fmt.Errorf("failed to create form file part: %w", err)
+	}
+	if _, err := io.Copy(part, file); err != nil {
+		return fmt.Errorf("failed to copy form file %q: %w", filePath, err)
+	}
+	return nil
+}
+
+// setDefaultContentType appends a Content-Type custom header unless one is
+// already present, so a user-supplied -H always wins.
+func setDefaultContentType(flags *HttpFlags, contentType string) {
+	for _, header := range flags.CustomHeaders {
+		if strings.HasPrefix(strings.ToLower(header), "content-type:") {
+			return
+		}
+	}
+	flags.CustomHeaders = append(flags.CustomHeaders, "Content-Type: "+contentType)
+}
+
+// hasChunkedTransferEncoding reports whether headers declares
+// "Transfer-Encoding: chunked", which signals that the request body
+// should be framed with a ChunkedWriter instead of a Content-Length.
+func hasChunkedTransferEncoding(headers []string) bool {
+	for _, header := range headers {
+		key, value, found := strings.Cut(header, ":")
+		if !found {
+			continue
+		}
+		if strings.EqualFold(strings.TrimSpace(key), "Transfer-Encoding") &&
+			strings.Contains(strings.ToLower(value), "chunked") {
+			return true
+		}
+	}
+	return false
+}
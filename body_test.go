@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestHttpRequestWithBody(t *testing.T) {
+	tests := []struct {
+		method string
+	}{
+		{"POST"},
+		{"PUT"},
+		{"DELETE"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			var receivedMethod string
+			var receivedBody string
+
+			mux := http.NewServeMux()
+			mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+				receivedMethod = r.Method
+				body, _ := io.ReadAll(r.Body)
+				receivedBody = string(body)
+				fmt.Fprint(w, "echo:"+receivedBody)
+			})
+
+			r, w, cleanup := runTestServer(t, mux, "127.0.0.1:8081")
+			defer cleanup()
+
+			flags := &HttpFlags{Method: tt.method, Data: "hello=world"}
+			body, err := prepareRequestBody(flags)
+			if err != nil {
+				t.Fatalf("prepareRequestBody failed: %v", err)
+			}
+			response := runHttpRequestAndCaptureOutput(t, r, w, tt.method, "http://127.0.0.1:8081/", body, os.Stdout, flags)
+
+			if receivedMethod != tt.method {
+				t.Fatalf("expected method %q, got %q", tt.method, receivedMethod)
+			}
+			if receivedBody != "hello=world" {
+				t.Fatalf("expected body %q, got %q", "hello=world", receivedBody)
+			}
+			if response != "echo:hello=world" {
+				t.Fatalf("expected response %q, got %q", "echo:hello=world", response)
+			}
+		})
+	}
+}
+
+func TestHttpRequestWithMultipartForm(t *testing.T) {
+	var receivedValue string
+	var receivedContentType string
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		receivedContentType = r.Header.Get("Content-Type")
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Fatalf("server failed to parse multipart form: %v", err)
+		}
+		receivedValue = r.FormValue("field")
+		fmt.Fprint(w, "ok")
+	})
+
+	r, w, cleanup := runTestServer(t, mux, "127.0.0.1:8082")
+	defer cleanup()
+
+	flags := &HttpFlags{Method: "POST", Form: []string{"field=value"}}
+	body, err := prepareRequestBody(flags)
+	if err != nil {
+		t.Fatalf("prepareRequestBody failed: %v", err)
+	}
+	response := runHttpRequestAndCaptureOutput(t, r, w, "POST", "http://127.0.0.1:8082/", body, os.Stdout, flags)
+
+	if !strings.HasPrefix(receivedContentType, "multipart/form-data; boundary=") {
+		t.Fatalf("expected multipart Content-Type, got %q", receivedContentType)
+	}
+	if receivedValue != "value" {
+		t.Fatalf("expected form field %q, got %q", "value", receivedValue)
+	}
+	if response != "ok" {
+		t.Fatalf("expected response %q, got %q", "ok", response)
+	}
+}
+
+func runHttpRequestAndCaptureOutput(t *testing.T, r, w *os.File, method, url string, body io.Reader, output io.Writer, flags *HttpFlags) string {
+	err := HttpRequest(method, url, body, output, flags)
+	if err != nil {
+		t.Fatalf("HttpRequest failed: %v", err)
+	}
+	w.Close()
+
+	var buf strings.Builder
+	io.Copy(&buf, r)
+	return buf.String()
+}
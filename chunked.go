@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+	"io"
+)
+
+// ChunkedWriter wraps an io.Writer, framing each Write call as an
+// HTTP/1.1 chunk per RFC 7230 §4.1. Call Close once all data has been
+// written to emit the terminating zero-size chunk.
+type ChunkedWriter struct {
+	w io.Writer
+}
+
+// NewChunkedWriter returns a ChunkedWriter that writes chunked-encoded
+// data to w.
+func NewChunkedWriter(w io.Writer) *ChunkedWriter {
+	return &ChunkedWriter{w: w}
+}
+
+func (c *ChunkedWriter) Write(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	if _, err := fmt.Fprintf(c.w, "%x\r\n", len(p)); err != nil {
+		return 0, fmt.Errorf("failed to write chunk size: %w", err)
+	}
+	if _, err := c.w.Write(p); err != nil {
+		return 0, fmt.Errorf("failed to write chunk data: %w", err)
+	}
+	if _, err := io.WriteString(c.w, "\r\n"); err != nil {
+		return 0, fmt.Errorf("failed to write chunk terminator: %w", err)
+	}
+	return len(p), nil
+}
+
+// Close writes the terminating zero-size chunk.
+func (c *ChunkedWriter) Close() error {
+	if _, err := io.WriteString(c.w, "0\r\n\r\n"); err != nil {
+		return fmt.Errorf("failed to write final chunk: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestReadChunkedResponseWithExtensionsAndTrailers(t *testing.T) {
+	raw := "5;ext=foo\r\nHello\r\n" +
+		"6\r\n, worl\r\n" +
+		"1\r\nd\r\n" +
+		"0\r\n" +
+		"X-Checksum: abc123\r\n" +
+		"X-Done: true\r\n" +
+		"\r\n"
+
+	var output bytes.Buffer
+	trailer, err := readChunkedResponse(strings.NewReader(raw), &output)
+	if err != nil {
+		t.Fatalf("readChunkedResponse failed: %v", err)
+	}
+	if output.String() != "Hello, world" {
+		t.Fatalf("expected body %q, got %q", "Hello, world", output.String())
+	}
+	if trailer["X-Checksum"] != "abc123" || trailer["X-Done"] != "true" {
+		t.Fatalf("expected trailers to be parsed, got %v", trailer)
+	}
+}
+
+func TestReadChunkedResponseWithoutTrailers(t *testing.T) {
+	raw := "5\r\nHello\r\n0\r\n\r\n"
+
+	var output bytes.Buffer
+	trailer, err := readChunkedResponse(strings.NewReader(raw), &output)
+	if err != nil {
+		t.Fatalf("readChunkedResponse failed: %v", err)
+	}
+	if output.String() != "Hello" {
+		t.Fatalf("expected body %q, got %q", "Hello", output.String())
+	}
+	if trailer != nil {
+		t.Fatalf("expected no trailers, got %v", trailer)
+	}
+}
+
+func TestHttpRequestSendsChunkedBody(t *testing.T) {
+	var receivedBody string
+	var receivedTransferEncoding string
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		receivedTransferEncoding = strings.Join(r.TransferEncoding, ",")
+		body, _ := io.ReadAll(r.Body)
+		receivedBody = string(body)
+		fmt.Fprint(w, "ok")
+	})
+
+	r, w, cleanup := runTestServer(t, mux, "127.0.0.1:8097")
+	defer cleanup()
+
+	flags := &HttpFlags{CustomHeaders: []string{"Transfer-Encoding: chunked"}}
+	response := runHttpRequestAndCaptureOutput(t, r, w, "POST", "http://127.0.0.1:8097/",
+		strings.NewReader("streamed body"), os.Stdout, flags)
+
+	if receivedTransferEncoding != "chunked" {
+		t.Fatalf("expected chunked Transfer-Encoding, got %q", receivedTransferEncoding)
+	}
+	if receivedBody != "streamed body" {
+		t.Fatalf("expected body %q, got %q", "streamed body", receivedBody)
+	}
+	if response != "ok" {
+		t.Fatalf("expected response %q, got %q", "ok", response)
+	}
+}
+
+func TestChunkedWriterRoundTrip(t *testing.T) {
+	var framed bytes.Buffer
+	cw := NewChunkedWriter(&framed)
+	if _, err := cw.Write([]byte("Hello, ")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if _, err := cw.Write([]byte("world!")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if err := cw.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	var output bytes.Buffer
+	trailer, err := readChunkedResponse(bytes.NewReader(framed.Bytes()), &output)
+	if err != nil {
+		t.Fatalf("readChunkedResponse failed: %v", err)
+	}
+	if output.String() != "Hello, world!" {
+		t.Fatalf("expected round-tripped body %q, got %q", "Hello, world!", output.String())
+	}
+	if trailer != nil {
+		t.Fatalf("expected no trailers, got %v", trailer)
+	}
+}
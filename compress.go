@@ -0,0 +1,113 @@
+package main
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+)
+
+// newDecodingWriter returns a writer that transparently decodes data
+// encoded per contentEncoding before forwarding it to output. It sits
+// between the chunked/identity framing layer and output, so framing
+// (readChunkedResponse / readNonChunkedResponse) is unaffected by
+// decoding. The returned writer must be closed to flush the final bytes
+// and surface any decoding error.
+func newDecodingWriter(output io.Writer, contentEncoding string) (io.WriteCloser, error) {
+	encodings := parseEncodings(contentEncoding)
+	if len(encodings) == 0 {
+		return nopWriteCloser{output}, nil
+	}
+	for _, encoding := range encodings {
+		if !isSupportedEncoding(encoding) {
+			return nil, fmt.Errorf("unsupported content encoding %q", encoding)
+		}
+	}
+
+	pipeReader, pipeWriter := io.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		reader, err := wrapDecoders(pipeReader, encodings)
+		if err != nil {
+			pipeReader.CloseWithError(err)
+			done <- err
+			return
+		}
+		_, err = io.Copy(output, reader)
+		done <- err
+	}()
+
+	return &decodingWriteCloser{pipeWriter: pipeWriter, done: done}, nil
+}
+
+// parseEncodings splits a Content-Encoding header value into the list of
+// codings that were applied, in the order they must be undone (i.e. the
+// reverse of the order in which they were applied to the payload).
+// "identity" is dropped since it requires no decoding.
+func parseEncodings(contentEncoding string) []string {
+	var encodings []string
+	for _, part := range strings.Split(contentEncoding, ",") {
+		encoding := strings.ToLower(strings.TrimSpace(part))
+		if encoding == "" || encoding == "identity" {
+			continue
+		}
+		encodings = append(encodings, encoding)
+	}
+	for i, j := 0, len(encodings)-1; i < j; i, j = i+1, j-1 {
+		encodings[i], encodings[j] = encodings[j], encodings[i]
+	}
+	return encodings
+}
+
+func isSupportedEncoding(encoding string) bool {
+	switch encoding {
+	case "gzip", "deflate", "br":
+		return true
+	default:
+		return false
+	}
+}
+
+// wrapDecoders chains a decoding io.Reader for each encoding onto r.
+func wrapDecoders(r io.Reader, encodings []string) (io.Reader, error) {
+	for _, encoding := range encodings {
+		switch encoding {
+		case "gzip":
+			gz, err := gzip.NewReader(r)
+			if err != nil {
+				return nil, fmt.Errorf("failed to initialize gzip decoder: %w", err)
+			}
+			r = gz
+		case "deflate":
+			r = flate.NewReader(r)
+		case "br":
+			r = brotli.NewReader(r)
+		}
+	}
+	return r, nil
+}
+
+// decodingWriteCloser feeds raw bytes into a pipe that a background
+// goroutine decodes and copies to the underlying output.
+type decodingWriteCloser struct {
+	pipeWriter *io.PipeWriter
+	done       chan error
+}
+
+func (d *decodingWriteCloser) Write(p []byte) (int, error) {
+	return d.pipeWriter.Write(p)
+}
+
+func (d *decodingWriteCloser) Close() error {
+	d.pipeWriter.Close()
+	return <-d.done
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"net/http"
+	"os"
+	"strconv"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+)
+
+func gzipBytes(t *testing.T, data string) []byte {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write([]byte(data)); err != nil {
+		t.Fatalf("failed to gzip test data: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("failed to close gzip writer: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func deflateBytes(t *testing.T, data string) []byte {
+	var buf bytes.Buffer
+	w, err := flate.NewWriter(&buf, flate.DefaultCompression)
+	if err != nil {
+		t.Fatalf("failed to create flate writer: %v", err)
+	}
+	if _, err := w.Write([]byte(data)); err != nil {
+		t.Fatalf("failed to deflate test data: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("failed to close flate writer: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func brotliBytes(t *testing.T, data string) []byte {
+	var buf bytes.Buffer
+	w := brotli.NewWriter(&buf)
+	if _, err := w.Write([]byte(data)); err != nil {
+		t.Fatalf("failed to brotli-compress test data: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("failed to close brotli writer: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestHttpGetDecodesCompressedResponses(t *testing.T) {
+	const content = "Hello, compressed world!"
+
+	tests := []struct {
+		name     string
+		encoding string
+		encode   func(t *testing.T, data string) []byte
+		addr     string
+		chunked  bool
+	}{
+		{"gzip", "gzip", gzipBytes, "127.0.0.1:8093", false},
+		{"deflate", "deflate", deflateBytes, "127.0.0.1:8094", false},
+		{"brotli", "br", brotliBytes, "127.0.0.1:8095", false},
+		{"gzip-chunked", "gzip", gzipBytes, "127.0.0.1:8096", true},
+		{"identity", "identity", func(t *testing.T, data string) []byte { return []byte(data) }, "127.0.0.1:8099", false},
+		{"deflate-then-gzip", "deflate, gzip", func(t *testing.T, data string) []byte {
+			return gzipBytes(t, string(deflateBytes(t, data)))
+		}, "127.0.0.1:8100", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoded := tt.encode(t, content)
+
+			mux := http.NewServeMux()
+			mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Encoding", tt.encoding)
+				if !tt.chunked {
+					w.Header().Set("Content-Length", strconv.Itoa(len(encoded)))
+					w.Write(encoded)
+					return
+				}
+				mid := len(encoded) / 2
+				w.Write(encoded[:mid])
+				if flusher, ok := w.(http.Flusher); ok {
+					flusher.Flush()
+				}
+				w.Write(encoded[mid:])
+			})
+
+			r, w, cleanup := runTestServer(t, mux, tt.addr)
+			defer cleanup()
+
+			flags := &HttpFlags{Compress: true}
+			response := runHttpGetAndCaptureOutput(t, r, w, "http://"+tt.addr+"/", os.Stdout, flags)
+			if response != content {
+				t.Fatalf("expected decoded response %q, got %q", content, response)
+			}
+		})
+	}
+}
@@ -0,0 +1,290 @@
+package main
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+
+	"golang.org/x/net/http2/hpack"
+)
+
+// http2Preface is the HTTP/2 connection preface every client must send
+// before any frames (RFC 7540 §3.5).
+const http2Preface = "PRI * HTTP/2.0\r\n\r\nSM\r\n\r\n"
+
+const (
+	frameData         = 0x0
+	frameHeaders      = 0x1
+	frameSettings     = 0x4
+	framePing         = 0x6
+	frameGoAway       = 0x7
+	frameWindowUpdate = 0x8
+)
+
+const (
+	flagEndStream  = 0x1
+	flagEndHeaders = 0x4
+	flagAck        = 0x1
+	flagPadded     = 0x8
+)
+
+// clientStreamID is the stream the client opens for its one request; a
+// minimal client never needs more than one.
+const clientStreamID = 1
+
+// doHTTP2 drives an HTTP/2 exchange over an already-negotiated conn and
+// writes the response to output, honoring the same ShowHeaders/
+// ShowOnlyHeaders/Compress flags as the HTTP/1.1 path. This minimal client
+// only ever sends a single END_STREAM HEADERS frame, so request bodies
+// are not supported over HTTP/2; callers must not negotiate h2 for a
+// request that has a body.
+func doHTTP2(conn net.Conn, method, path, host string, output io.Writer, flags *HttpFlags) (string, error) {
+	resp, err := http2RoundTrip(conn, method, path, host, flags.CustomHeaders, flags.Compress)
+	if err != nil {
+		return "", err
+	}
+
+	if flags.ShowHeaders || flags.ShowOnlyHeaders {
+		io.WriteString(output, resp.StatusLine+"\r\n"+resp.Headers+"\r\n")
+		if flags.ShowOnlyHeaders {
+			return resp.StatusLine + "\r\n" + resp.Headers, nil
+		}
+	}
+
+	if !flags.Compress {
+		_, err := output.Write(resp.Body)
+		return resp.StatusLine + "\r\n" + resp.Headers, err
+	}
+
+	encoding, _ := headerValue(resp.Headers, "Content-Encoding")
+	decodingWriter, err := newDecodingWriter(output, encoding)
+	if err != nil {
+		return resp.StatusLine + "\r\n" + resp.Headers, err
+	}
+	if _, err := decodingWriter.Write(resp.Body); err != nil {
+		return resp.StatusLine + "\r\n" + resp.Headers, err
+	}
+	return resp.StatusLine + "\r\n" + resp.Headers, decodingWriter.Close()
+}
+
+// isHTTP2Negotiated reports whether conn completed a TLS handshake that
+// selected "h2" via ALPN.
+func isHTTP2Negotiated(conn net.Conn) bool {
+	tlsConn, ok := conn.(*tls.Conn)
+	return ok && tlsConn.ConnectionState().NegotiatedProtocol == "h2"
+}
+
+// http2RoundTrip speaks just enough HTTP/2 to issue a single request on
+// clientStreamID and collect its response: the connection preface, an
+// empty SETTINGS frame, one HEADERS frame carrying the HPACK-encoded
+// pseudo-headers and any custom headers, then HEADERS/DATA frames read
+// back until END_STREAM.
+func http2RoundTrip(conn net.Conn, method, path, host string, customHeaders []string, compress bool) (*Response, error) {
+	if _, err := io.WriteString(conn, http2Preface); err != nil {
+		return nil, fmt.Errorf("failed to write HTTP/2 preface: %w", err)
+	}
+	if err := writeFrame(conn, frameSettings, 0, 0, nil); err != nil {
+		return nil, fmt.Errorf("failed to write SETTINGS frame: %w", err)
+	}
+
+	headerBlock, err := encodeHTTP2Headers(method, path, host, customHeaders, compress)
+	if err != nil {
+		return nil, err
+	}
+	if err := writeFrame(conn, frameHeaders, flagEndHeaders|flagEndStream, clientStreamID, headerBlock); err != nil {
+		return nil, fmt.Errorf("failed to write HEADERS frame: %w", err)
+	}
+
+	return readHTTP2Response(conn, clientStreamID)
+}
+
+func encodeHTTP2Headers(method, path, host string, customHeaders []string, compress bool) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := hpack.NewEncoder(&buf)
+
+	pseudoHeaders := []hpack.HeaderField{
+		{Name: ":method", Value: method},
+		{Name: ":scheme", Value: "https"},
+		{Name: ":authority", Value: host},
+		{Name: ":path", Value: path},
+	}
+	for _, field := range pseudoHeaders {
+		if err := encoder.WriteField(field); err != nil {
+			return nil, fmt.Errorf("failed to encode pseudo-header %q: %w", field.Name, err)
+		}
+	}
+
+	for _, header := range customHeaders {
+		name, value, found := strings.Cut(header, ":")
+		if !found {
+			continue
+		}
+		field := hpack.HeaderField{Name: strings.ToLower(strings.TrimSpace(name)), Value: strings.TrimSpace(value)}
+		if err := encoder.WriteField(field); err != nil {
+			return nil, fmt.Errorf("failed to encode header %q: %w", field.Name, err)
+		}
+	}
+
+	if compress {
+		field := hpack.HeaderField{Name: "accept-encoding", Value: "gzip, deflate, br"}
+		if err := encoder.WriteField(field); err != nil {
+			return nil, fmt.Errorf("failed to encode Accept-Encoding header: %w", err)
+		}
+	}
+
+	return buf.Bytes(), nil
+}
+
+// readHTTP2Response reads frames until the response on streamID completes,
+// acknowledging SETTINGS/PING and replenishing flow-control windows for
+// any DATA received.
+func readHTTP2Response(conn net.Conn, streamID uint32) (*Response, error) {
+	decoder := hpack.NewDecoder(4096, nil)
+	var statusLine string
+	var headerLines strings.Builder
+	var body bytes.Buffer
+
+	for {
+		header, payload, err := readFrame(conn)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read HTTP/2 frame: %w", err)
+		}
+
+		switch header.typ {
+		case frameSettings:
+			if header.flags&flagAck == 0 {
+				if err := writeFrame(conn, frameSettings, flagAck, 0, nil); err != nil {
+					return nil, fmt.Errorf("failed to ack SETTINGS frame: %w", err)
+				}
+			}
+		case framePing:
+			if header.flags&flagAck == 0 {
+				if err := writeFrame(conn, framePing, flagAck, 0, payload); err != nil {
+					return nil, fmt.Errorf("failed to ack PING frame: %w", err)
+				}
+			}
+		case frameGoAway:
+			return nil, fmt.Errorf("server sent GOAWAY before completing the response")
+		case frameWindowUpdate:
+			// This client never streams a request body large enough to
+			// exhaust its send window, so outgoing WINDOW_UPDATEs are
+			// simply ignored.
+		case frameHeaders:
+			if header.streamID != streamID {
+				continue
+			}
+			fields, err := decoder.DecodeFull(stripPadding(payload, header.flags))
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode HPACK headers: %w", err)
+			}
+			for _, field := range fields {
+				if field.Name == ":status" {
+					statusLine = "HTTP/2 " + field.Value
+					continue
+				}
+				headerLines.WriteString(field.Name + ": " + field.Value + "\r\n")
+			}
+			if header.flags&flagEndStream != 0 {
+				return &Response{StatusLine: statusLine, Headers: headerLines.String(), Body: body.Bytes()}, nil
+			}
+		case frameData:
+			if header.streamID != streamID {
+				continue
+			}
+			data := stripPadding(payload, header.flags)
+			body.Write(data)
+			if err := replenishFlowControl(conn, streamID, uint32(len(payload))); err != nil {
+				return nil, err
+			}
+			if header.flags&flagEndStream != 0 {
+				return &Response{StatusLine: statusLine, Headers: headerLines.String(), Body: body.Bytes()}, nil
+			}
+		}
+	}
+}
+
+func replenishFlowControl(conn net.Conn, streamID uint32, consumed uint32) error {
+	if consumed == 0 {
+		return nil
+	}
+	increment := windowIncrementPayload(consumed)
+	if err := writeFrame(conn, frameWindowUpdate, 0, 0, increment); err != nil {
+		return fmt.Errorf("failed to send connection WINDOW_UPDATE: %w", err)
+	}
+	if err := writeFrame(conn, frameWindowUpdate, 0, streamID, increment); err != nil {
+		return fmt.Errorf("failed to send stream WINDOW_UPDATE: %w", err)
+	}
+	return nil
+}
+
+func windowIncrementPayload(n uint32) []byte {
+	buf := make([]byte, 4)
+	binary.BigEndian.PutUint32(buf, n&0x7fffffff)
+	return buf
+}
+
+// stripPadding removes the PADDED-flag framing (a 1-byte pad length
+// followed by that many trailing padding bytes) from a HEADERS or DATA
+// frame payload.
+func stripPadding(payload []byte, flags byte) []byte {
+	if flags&flagPadded == 0 || len(payload) == 0 {
+		return payload
+	}
+	padLen := int(payload[0])
+	if padLen+1 > len(payload) {
+		return payload[1:]
+	}
+	return payload[1 : len(payload)-padLen]
+}
+
+type http2FrameHeader struct {
+	length   uint32
+	typ      byte
+	flags    byte
+	streamID uint32
+}
+
+// writeFrame writes a frame header and payload per RFC 7540 §4.1.
+func writeFrame(w io.Writer, typ, flags byte, streamID uint32, payload []byte) error {
+	header := make([]byte, 9)
+	header[0] = byte(len(payload) >> 16)
+	header[1] = byte(len(payload) >> 8)
+	header[2] = byte(len(payload))
+	header[3] = typ
+	header[4] = flags
+	binary.BigEndian.PutUint32(header[5:], streamID&0x7fffffff)
+
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+	if len(payload) > 0 {
+		if _, err := w.Write(payload); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readFrame reads one frame header and its payload.
+func readFrame(r io.Reader) (http2FrameHeader, []byte, error) {
+	raw := make([]byte, 9)
+	if _, err := io.ReadFull(r, raw); err != nil {
+		return http2FrameHeader{}, nil, err
+	}
+	header := http2FrameHeader{
+		length:   uint32(raw[0])<<16 | uint32(raw[1])<<8 | uint32(raw[2]),
+		typ:      raw[3],
+		flags:    raw[4],
+		streamID: binary.BigEndian.Uint32(raw[5:]) & 0x7fffffff,
+	}
+
+	payload := make([]byte, header.length)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return http2FrameHeader{}, nil, err
+	}
+	return header, payload, nil
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/http2"
+)
+
+func TestHttpGetOverHTTP2(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.ProtoMajor != 2 {
+			t.Errorf("expected an HTTP/2 request, got proto %q", r.Proto)
+		}
+		fmt.Fprint(w, "Hello over HTTP/2")
+	})
+
+	server := httptest.NewUnstartedServer(mux)
+	if err := http2.ConfigureServer(server.Config, &http2.Server{}); err != nil {
+		t.Fatalf("failed to configure HTTP/2 test server: %v", err)
+	}
+	server.TLS = server.Config.TLSConfig
+	server.StartTLS()
+	defer server.Close()
+
+	var output bytes.Buffer
+	flags := &HttpFlags{HTTP2: true, Insecure: true}
+
+	if err := HttpGet(server.URL, &output, flags); err != nil {
+		t.Fatalf("HttpGet failed: %v", err)
+	}
+	if output.String() != "Hello over HTTP/2" {
+		t.Fatalf("expected %q, got %q", "Hello over HTTP/2", output.String())
+	}
+}
+
+func TestHttpRequestWithBodyFallsBackToHTTP1AgainstH2Server(t *testing.T) {
+	var receivedProto int
+	var receivedBody string
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		receivedProto = r.ProtoMajor
+		body, _ := io.ReadAll(r.Body)
+		receivedBody = string(body)
+		fmt.Fprint(w, "ok")
+	})
+
+	server := httptest.NewUnstartedServer(mux)
+	if err := http2.ConfigureServer(server.Config, &http2.Server{}); err != nil {
+		t.Fatalf("failed to configure HTTP/2 test server: %v", err)
+	}
+	server.TLS = server.Config.TLSConfig
+	server.StartTLS()
+	defer server.Close()
+
+	var output bytes.Buffer
+	flags := &HttpFlags{HTTP2: true, Insecure: true}
+
+	if err := HttpRequest("POST", server.URL, strings.NewReader("hello=world"), &output, flags); err != nil {
+		t.Fatalf("HttpRequest failed: %v", err)
+	}
+	if receivedProto != 1 {
+		t.Fatalf("expected a request with a body to fall back to HTTP/1.1, got proto major %d", receivedProto)
+	}
+	if receivedBody != "hello=world" {
+		t.Fatalf("expected body %q, got %q", "hello=world", receivedBody)
+	}
+	if output.String() != "ok" {
+		t.Fatalf("expected response %q, got %q", "ok", output.String())
+	}
+}
+
+func TestHttpRequestFollowsRedirectOverHTTP2(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/start", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/final", http.StatusFound)
+	})
+	mux.HandleFunc("/final", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "landed")
+	})
+
+	server := httptest.NewUnstartedServer(mux)
+	if err := http2.ConfigureServer(server.Config, &http2.Server{}); err != nil {
+		t.Fatalf("failed to configure HTTP/2 test server: %v", err)
+	}
+	server.TLS = server.Config.TLSConfig
+	server.StartTLS()
+	defer server.Close()
+
+	var output bytes.Buffer
+	flags := &HttpFlags{HTTP2: true, Insecure: true, FollowRedirects: true}
+
+	if err := HttpRequest("GET", server.URL+"/start", nil, &output, flags); err != nil {
+		t.Fatalf("HttpRequest failed: %v", err)
+	}
+	if output.String() != "landed" {
+		t.Fatalf("expected redirect to be followed to %q, got %q", "landed", output.String())
+	}
+}
+
+func TestHttpGetFallsBackToHTTP1WhenServerLacksALPN(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.ProtoMajor != 1 {
+			t.Errorf("expected an HTTP/1.1 request, got proto %q", r.Proto)
+		}
+		fmt.Fprint(w, "Hello over HTTP/1.1")
+	})
+
+	server := httptest.NewTLSServer(mux)
+	defer server.Close()
+
+	var output bytes.Buffer
+	flags := &HttpFlags{HTTP2: true, Insecure: true}
+
+	if err := HttpGet(server.URL, &output, flags); err != nil {
+		t.Fatalf("HttpGet failed: %v", err)
+	}
+	if output.String() != "Hello over HTTP/1.1" {
+		t.Fatalf("expected %q, got %q", "Hello over HTTP/1.1", output.String())
+	}
+}
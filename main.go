@@ -4,7 +4,6 @@ import (
 	"bufio"
 	"fmt"
 	"io"
-	"net"
 	"os"
 	"strconv"
 	"strings"
@@ -17,6 +16,18 @@ type HttpFlags struct {
 	ShowOnlyHeaders bool
 	CustomHeaders   []string
 	OutputFile      string
+	Insecure        bool
+	CACert          string
+	ClientCert      string
+	ClientKey       string
+	Method          string
+	Data            string
+	Form            []string
+	FollowRedirects bool
+	MaxRedirects    int
+	Verbose         bool
+	Compress        bool
+	HTTP2           bool
 }
 
 func parseFlags() *HttpFlags {
@@ -25,6 +36,19 @@ func parseFlags() *HttpFlags {
 	var customHeaders []string
 	pflag.StringSliceVar(&customHeaders, "H", nil, "Custom headers to include in the request")
 	outputFile := pflag.String("o", "", "Output to file instead of stdout")
+	insecure := pflag.BoolP("insecure", "k", false, "Skip TLS certificate verification")
+	caCert := pflag.String("cacert", "", "Path to a PEM bundle used to verify the server certificate")
+	clientCert := pflag.String("cert", "", "Path to a client certificate for mutual TLS")
+	clientKey := pflag.String("key", "", "Path to the private key matching --cert")
+	method := pflag.StringP("request", "X", "GET", "HTTP method to use")
+	data := pflag.StringP("data", "d", "", "Send data in the request body (use @file to read from a file)")
+	var form []string
+	pflag.StringArrayVarP(&form, "form", "F", nil, "Add a multipart form field: key=value or key=@file")
+	followRedirects := pflag.BoolP("location", "L", false, "Follow redirects")
+	maxRedirects := pflag.Int("max-redirs", defaultMaxRedirects, "Maximum number of redirects to follow")
+	verbose := pflag.BoolP("verbose", "v", false, "Print a trace line to stderr for each redirect hop")
+	compress := pflag.Bool("compressed", false, "Request a compressed response and transparently decode it")
+	http2 := pflag.Bool("http2", true, "Negotiate HTTP/2 via ALPN for https URLs, falling back to HTTP/1.1")
 
 	pflag.Parse()
 
@@ -33,6 +57,18 @@ func parseFlags() *HttpFlags {
 		ShowOnlyHeaders: *showOnlyHeaders,
 		CustomHeaders:   customHeaders,
 		OutputFile:      *outputFile,
+		Insecure:        *insecure,
+		CACert:          *caCert,
+		ClientCert:      *clientCert,
+		ClientKey:       *clientKey,
+		Method:          *method,
+		Data:            *data,
+		Form:            form,
+		FollowRedirects: *followRedirects,
+		MaxRedirects:    *maxRedirects,
+		Verbose:         *verbose,
+		Compress:        *compress,
+		HTTP2:           *http2,
 	}
 }
 
@@ -47,21 +83,6 @@ func CreateOutputFile(fileName string) (*os.File, error) {
 	return file, nil
 }
 
-func parseURL(url string) (string, string, string) {
-	parts := strings.Split(url, "/")
-	hostPart := parts[2]
-	hostParts := strings.Split(hostPart, ":")
-	host := hostParts[0]
-	var port string
-	if len(hostParts) > 1 {
-		port = hostParts[1]
-	} else {
-		port = "80"
-	}
-	path := "/" + strings.Join(parts[3:], "/")
-	return host, port, path
-}
-
 func readHeaders(reader *bufio.Reader) (string, bool, error) {
 	var headers strings.Builder
 	var isChunked bool
@@ -133,94 +154,202 @@ func discardBytes(reader io.Reader, n int) error {
 	return err
 }
 
-func readChunkedResponse(reader io.Reader, output io.Writer) error {
+// stripChunkExtensions removes any semicolon-delimited chunk extensions
+// (RFC 7230 §4.1.1) from a chunk size line, e.g. "4;foo=bar" -> "4".
+func stripChunkExtensions(sizeLine string) string {
+	if idx := strings.IndexByte(sizeLine, ';'); idx != -1 {
+		return trimSpace(sizeLine[:idx])
+	}
+	return sizeLine
+}
+
+// readTrailers reads the trailer header block that follows the
+// terminating zero-size chunk, up to (and consuming) the blank line that
+// ends it. It returns a nil map when there were no trailers.
+func readTrailers(reader io.Reader) (map[string]string, error) {
+	var trailers map[string]string
+	for {
+		line, err := readLine(reader)
+		if err != nil {
+			return nil, err
+		}
+		line = trimSpace(line)
+		if line == "" {
+			return trailers, nil
+		}
+		key, value, found := strings.Cut(line, ":")
+		if !found {
+			return nil, fmt.Errorf("malformed trailer line %q", line)
+		}
+		if trailers == nil {
+			trailers = make(map[string]string)
+		}
+		trailers[trimSpace(key)] = trimSpace(value)
+	}
+}
+
+// readChunkedResponse reads a chunked response body from reader, writing
+// decoded chunk data to output, and returns any trailer headers sent after
+// the terminating zero-size chunk.
+func readChunkedResponse(reader io.Reader, output io.Writer) (map[string]string, error) {
+	// Wrap once: readLine only wraps bare readers in an ephemeral
+	// bufio.Reader, and a fresh one per call would each buffer-ahead and
+	// discard unread bytes, losing data between iterations.
+	bufReader, ok := reader.(*bufio.Reader)
+	if !ok {
+		bufReader = bufio.NewReader(reader)
+	}
 
 	for {
 
 		// Read chunk size line
-		sizeLine, err := readLine(reader)
+		sizeLine, err := readLine(bufReader)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
-		// Trim whitespace
-		sizeLine = trimSpace(sizeLine)
+		// Trim whitespace and any chunk extensions
+		sizeLine = stripChunkExtensions(trimSpace(sizeLine))
 
-		// Check for empty line
+		// Skip a stray blank line between chunks
 		if sizeLine == "" {
-			break
+			continue
 		}
 
 		// Parse chunk size
 		size, err := parseIntHex(sizeLine)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
-		// End of response
+		// End of response; read any trailer headers
 		if size == 0 {
-			break
+			return readTrailers(bufReader)
 		}
 
 		// Read chunk data
 		chunkData := make([]byte, size)
-		if err := readBytes(reader, chunkData); err != nil {
-			return err
+		if err := readBytes(bufReader, chunkData); err != nil {
+			return nil, err
 		}
 
 		// Write chunk data
 		if err := write(chunkData, output); err != nil {
-			return err
+			return nil, err
 		}
 
 		// Discard trailing CRLF
-		if err := discardBytes(reader, 2); err != nil {
-			return err
+		if err := discardBytes(bufReader, 2); err != nil {
+			return nil, err
 		}
 	}
-
-	return nil
 }
 
+// HttpGet performs a GET request. It is a thin wrapper around HttpRequest.
 func HttpGet(url string, output io.Writer, flags *HttpFlags) error {
-	host, port, path := parseURL(url)
-	conn, err := net.Dial("tcp", host+":"+port)
+	return HttpRequest("GET", url, nil, output, flags)
+}
+
+// doHTTP performs a single HTTP exchange against url, writing the response
+// to output and returning the raw response headers so callers can inspect
+// the status line (e.g. to follow a redirect). Non-HTTP/2 connections are
+// drawn from and returned to transport's idle pool, so e.g. a redirect
+// chain back to the same host reuses one connection.
+func doHTTP(transport *Transport, method, url string, bodyBytes []byte, output io.Writer, flags *HttpFlags) (string, error) {
+	scheme, host, port, path, err := parseURL(url)
 	if err != nil {
-		return err
+		return "", err
 	}
-	defer conn.Close()
 
-	requestLine := fmt.Sprintf("GET %s HTTP/1.1\r\n", path)
-	requestHeaders := fmt.Sprintf("Host: %s\r\n", host)
-	for _, header := range flags.CustomHeaders {
-		requestHeaders += fmt.Sprintf(header + "\r\n")
+	key := connKey(scheme, host, port)
+	conn := transport.getIdleConn(key)
+	if conn == nil {
+		// This client's HTTP/2 path only ever sends a single END_STREAM
+		// HEADERS frame, so it can't carry a request body. Don't even offer
+		// h2 via ALPN when there's a body to send, so the server negotiates
+		// HTTP/1.1 and the body is actually delivered instead of silently
+		// dropped.
+		dialFlags := flags
+		if len(bodyBytes) > 0 && flags.HTTP2 {
+			withoutHTTP2 := *flags
+			withoutHTTP2.HTTP2 = false
+			dialFlags = &withoutHTTP2
+		}
+
+		conn, err = dial(scheme, host, port, dialFlags)
+		if err != nil {
+			return "", err
+		}
+		if scheme == "https" && dialFlags.HTTP2 && isHTTP2Negotiated(conn) {
+			defer conn.Close()
+			return doHTTP2(conn, method, path, host, output, flags)
+		}
+	}
+
+	// Only return conn to the pool once its response has been fully
+	// drained; otherwise the leftover bytes on the wire would corrupt
+	// whatever request reuses it next.
+	succeeded := false
+	defer func() {
+		if succeeded {
+			transport.putIdleConn(key, conn)
+		} else {
+			conn.Close()
+		}
+	}()
+
+	chunkedBody := len(bodyBytes) > 0 && hasChunkedTransferEncoding(flags.CustomHeaders)
+
+	reqHeaders := flags.CustomHeaders
+	if flags.Compress {
+		reqHeaders = append(append([]string{}, reqHeaders...), "Accept-Encoding: gzip, deflate, br")
+	}
+	head := buildRequestHead(method, host, path, reqHeaders, len(bodyBytes), chunkedBody, "keep-alive")
+	if _, err := conn.Write([]byte(head)); err != nil {
+		return "", fmt.Errorf("failed to write request: %w", err)
+	}
+	if err := writeRequestBody(conn, bodyBytes, chunkedBody); err != nil {
+		return "", err
 	}
-	requestHeaders += "Connection: close\r\n\r\n"
-	request := requestLine + requestHeaders
-	conn.Write([]byte(request))
 
 	reader := bufio.NewReader(conn)
 
 	// Read headers
 	headers, isChunked, err := readHeaders(reader)
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	if flags.ShowHeaders || flags.ShowOnlyHeaders {
 		output.Write([]byte(headers)) // Print headers
 		if flags.ShowOnlyHeaders {
-			return nil // Return early if only headers should be shown
+			return headers, nil // Return early if only headers should be shown
 		}
 	}
 
-	if isChunked {
-		readChunkedResponse(reader, output)
-	} else {
-		readNonChunkedResponse(reader, output)
+	bodyOutput := output
+	var decodingWriter io.WriteCloser
+	if flags.Compress {
+		encoding, _ := headerValue(headers, "Content-Encoding")
+		decodingWriter, err = newDecodingWriter(output, encoding)
+		if err != nil {
+			return headers, err
+		}
+		bodyOutput = decodingWriter
+	}
+
+	if _, err := readResponseBody(reader, headers, isChunked, bodyOutput); err != nil {
+		return headers, err
+	}
+
+	if decodingWriter != nil {
+		if err := decodingWriter.Close(); err != nil {
+			return headers, err
+		}
 	}
 
-	return nil
+	succeeded = true
+	return headers, nil
 }
 
 func main() {
@@ -249,5 +378,13 @@ func main() {
 		output = file
 	}
 
-	HttpGet(url, output, flags)
+	body, err := prepareRequestBody(flags)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return
+	}
+
+	if err := HttpRequest(flags.Method, url, body, output, flags); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
 }
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// defaultMaxRedirects is used when HttpFlags.MaxRedirects is zero.
+const defaultMaxRedirects = 10
+
+// HttpRequest performs an HTTP request with the given method and optional
+// body against url, writing the response to output. When flags.FollowRedirects
+// is set, 3xx responses are followed automatically per followRedirects.
+func HttpRequest(method, url string, body io.Reader, output io.Writer, flags *HttpFlags) error {
+	var bodyBytes []byte
+	if body != nil {
+		var err error
+		bodyBytes, err = io.ReadAll(body)
+		if err != nil {
+			return fmt.Errorf("failed to read request body: %w", err)
+		}
+	}
+	return followRedirects(method, url, bodyBytes, output, flags)
+}
+
+// followRedirects drives the request/redirect loop: it performs a request,
+// and if the response is a redirect status and flags.FollowRedirects is set,
+// re-issues the request against the resolved Location until a non-redirect
+// response is reached, a redirect loop is detected, or MaxRedirects is hit.
+// A single Transport is shared across every hop, so a redirect chain back
+// to the same host reuses one connection instead of dialing fresh each time.
+func followRedirects(method, urlStr string, bodyBytes []byte, output io.Writer, flags *HttpFlags) error {
+	maxRedirects := flags.MaxRedirects
+	if maxRedirects <= 0 {
+		maxRedirects = defaultMaxRedirects
+	}
+
+	transport := &Transport{}
+	defer transport.Close()
+
+	visited := make(map[string]bool)
+	currentMethod, currentBody, currentURL := method, bodyBytes, urlStr
+
+	for hop := 0; ; hop++ {
+		if visited[currentURL] {
+			return fmt.Errorf("redirect loop detected at %s", currentURL)
+		}
+		visited[currentURL] = true
+
+		var buf bytes.Buffer
+		headers, err := doHTTP(transport, currentMethod, currentURL, currentBody, &buf, flags)
+		if err != nil {
+			return err
+		}
+
+		status, statusErr := parseStatusCode(headers)
+		if !flags.FollowRedirects || statusErr != nil || !isRedirectStatus(status) {
+			_, err := output.Write(buf.Bytes())
+			return err
+		}
+
+		if hop >= maxRedirects {
+			return fmt.Errorf("stopped after %d redirects", maxRedirects)
+		}
+
+		location, ok := headerValue(headers, "Location")
+		if !ok {
+			return fmt.Errorf("redirect status %d without Location header", status)
+		}
+		nextURL, err := resolveURL(currentURL, location)
+		if err != nil {
+			return fmt.Errorf("failed to resolve redirect location %q: %w", location, err)
+		}
+
+		if flags.Verbose {
+			fmt.Fprintf(os.Stderr, "-> %d redirect to %s\n", status, nextURL)
+		}
+
+		switch {
+		case status == 303:
+			currentMethod, currentBody = "GET", nil
+			stripContentTypeHeader(flags)
+		case status == 301 || status == 302:
+			if currentMethod == "POST" || currentMethod == "PUT" {
+				currentMethod, currentBody = "GET", nil
+				stripContentTypeHeader(flags)
+			}
+		}
+
+		currentURL = nextURL
+	}
+}
+
+// stripContentTypeHeader removes any Content-Type header from
+// flags.CustomHeaders. It's called when a redirect downgrades the request
+// to a bodyless GET, so the re-issued request doesn't carry a stale
+// Content-Type left over from prepareRequestBody's original body.
+func stripContentTypeHeader(flags *HttpFlags) {
+	headers := flags.CustomHeaders[:0]
+	for _, header := range flags.CustomHeaders {
+		if strings.HasPrefix(strings.ToLower(header), "content-type:") {
+			continue
+		}
+		headers = append(headers, header)
+	}
+	flags.CustomHeaders = headers
+}
+
+func isRedirectStatus(status int) bool {
+	switch status {
+	case 301, 302, 303, 307, 308:
+		return true
+	default:
+		return false
+	}
+}
+
+// parseStatusCode extracts the numeric status code from a raw header
+// block's status line (e.g. "HTTP/1.1 301 Moved Permanently").
+func parseStatusCode(headers string) (int, error) {
+	statusLine := headers
+	if idx := strings.Index(headers, "\r\n"); idx != -1 {
+		statusLine = headers[:idx]
+	}
+	fields := strings.SplitN(statusLine, " ", 3)
+	if len(fields) < 2 {
+		return 0, fmt.Errorf("malformed status line %q", statusLine)
+	}
+	return strconv.Atoi(fields[1])
+}
+
+// headerValue looks up a header by name (case-insensitively) in a raw
+// header block.
+func headerValue(headers, name string) (string, bool) {
+	prefix := strings.ToLower(name) + ":"
+	for _, line := range strings.Split(headers, "\r\n") {
+		if len(line) <= len(prefix) {
+			continue
+		}
+		if strings.ToLower(line[:len(prefix)]) == prefix {
+			return strings.TrimSpace(line[len(prefix):]), true
+		}
+	}
+	return "", false
+}
+
+// resolveURL resolves a Location header value against the URL of the
+// request that produced it, supporting both absolute and relative targets.
+func resolveURL(base, location string) (string, error) {
+	baseURL, err := url.Parse(base)
+	if err != nil {
+		return "", err
+	}
+	locationURL, err := url.Parse(location)
+	if err != nil {
+		return "", err
+	}
+	return baseURL.ResolveReference(locationURL).String(), nil
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"testing"
+)
+
+func TestHttpRequestFollowsRedirect(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/start", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/final", http.StatusFound)
+	})
+	mux.HandleFunc("/final", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "landed")
+	})
+
+	r, w, cleanup := runTestServer(t, mux, "127.0.0.1:8088")
+	defer cleanup()
+
+	flags := &HttpFlags{FollowRedirects: true}
+	response := runHttpRequestAndCaptureOutput(t, r, w, "GET", "http://127.0.0.1:8088/start", nil, os.Stdout, flags)
+	if response != "landed" {
+		t.Fatalf("expected response %q, got %q", "landed", response)
+	}
+}
+
+func TestHttpRequestDoesNotFollowByDefault(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/start", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/final", http.StatusFound)
+	})
+	mux.HandleFunc("/final", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "landed")
+	})
+
+	r, w, cleanup := runTestServer(t, mux, "127.0.0.1:8089")
+	defer cleanup()
+
+	flags := &HttpFlags{}
+	response := runHttpRequestAndCaptureOutput(t, r, w, "GET", "http://127.0.0.1:8089/start", nil, os.Stdout, flags)
+	want := "<a href=\"/final\">Found</a>.\n\n"
+	if response != want {
+		t.Fatalf("expected unfollowed redirect body %q, got %q", want, response)
+	}
+}
+
+func TestHttpRequestRedirectDowngradesPostTo303(t *testing.T) {
+	var finalMethod string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/start", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/final", http.StatusSeeOther)
+	})
+	mux.HandleFunc("/final", func(w http.ResponseWriter, r *http.Request) {
+		finalMethod = r.Method
+		fmt.Fprint(w, "ok")
+	})
+
+	r, w, cleanup := runTestServer(t, mux, "127.0.0.1:8090")
+	defer cleanup()
+
+	flags := &HttpFlags{FollowRedirects: true, Data: "x=1"}
+	body, err := prepareRequestBody(flags)
+	if err != nil {
+		t.Fatalf("prepareRequestBody failed: %v", err)
+	}
+	runHttpRequestAndCaptureOutput(t, r, w, "POST", "http://127.0.0.1:8090/start", body, os.Stdout, flags)
+
+	if finalMethod != "GET" {
+		t.Fatalf("expected 303 redirect to downgrade to GET, got %q", finalMethod)
+	}
+}
+
+func TestHttpRequestRedirectDropsContentTypeOn303(t *testing.T) {
+	var finalContentType string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/start", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/final", http.StatusSeeOther)
+	})
+	mux.HandleFunc("/final", func(w http.ResponseWriter, r *http.Request) {
+		finalContentType = r.Header.Get("Content-Type")
+		fmt.Fprint(w, "ok")
+	})
+
+	r, w, cleanup := runTestServer(t, mux, "127.0.0.1:8098")
+	defer cleanup()
+
+	flags := &HttpFlags{FollowRedirects: true, Data: "x=1"}
+	body, err := prepareRequestBody(flags)
+	if err != nil {
+		t.Fatalf("prepareRequestBody failed: %v", err)
+	}
+	runHttpRequestAndCaptureOutput(t, r, w, "POST", "http://127.0.0.1:8098/start", body, os.Stdout, flags)
+
+	if finalContentType != "" {
+		t.Fatalf("expected no Content-Type on the bodyless redirected GET, got %q", finalContentType)
+	}
+}
+
+func TestHttpRequestRedirectPreservesMethodOn307(t *testing.T) {
+	var finalMethod, finalBody string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/start", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/final", http.StatusTemporaryRedirect)
+	})
+	mux.HandleFunc("/final", func(w http.ResponseWriter, r *http.Request) {
+		finalMethod = r.Method
+		b, _ := io.ReadAll(r.Body)
+		finalBody = string(b)
+		fmt.Fprint(w, "ok")
+	})
+
+	r, w, cleanup := runTestServer(t, mux, "127.0.0.1:8091")
+	defer cleanup()
+
+	flags := &HttpFlags{FollowRedirects: true, Data: "x=1"}
+	body, err := prepareRequestBody(flags)
+	if err != nil {
+		t.Fatalf("prepareRequestBody failed: %v", err)
+	}
+	runHttpRequestAndCaptureOutput(t, r, w, "POST", "http://127.0.0.1:8091/start", body, os.Stdout, flags)
+
+	if finalMethod != "POST" {
+		t.Fatalf("expected 307 redirect to preserve method, got %q", finalMethod)
+	}
+	if finalBody != "x=1" {
+		t.Fatalf("expected 307 redirect to preserve body, got %q", finalBody)
+	}
+}
+
+func TestHttpRequestRedirectLoopDetected(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/a", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/b", http.StatusFound)
+	})
+	mux.HandleFunc("/b", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/a", http.StatusFound)
+	})
+
+	r, w, cleanup := runTestServer(t, mux, "127.0.0.1:8092")
+	defer cleanup()
+
+	flags := &HttpFlags{FollowRedirects: true}
+	err := HttpRequest("GET", "http://127.0.0.1:8092/a", nil, os.Stdout, flags)
+	w.Close()
+	io.Copy(io.Discard, r)
+	if err == nil {
+		t.Fatal("expected an error for a redirect loop, got nil")
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"os"
+)
+
+// dial opens a connection to host:port, transparently upgrading to TLS
+// when scheme is "https".
+func dial(scheme, host, port string, flags *HttpFlags) (net.Conn, error) {
+	if scheme == "https" {
+		return dialTLS(host, port, flags)
+	}
+	return net.Dial("tcp", net.JoinHostPort(host, port))
+}
+
+// dialTLS establishes a TLS connection to host:port, applying the
+// certificate verification options from flags.
+func dialTLS(host, port string, flags *HttpFlags) (net.Conn, error) {
+	config := &tls.Config{
+		ServerName:         host,
+		InsecureSkipVerify: flags.Insecure,
+	}
+	if flags.HTTP2 {
+		config.NextProtos = []string{"h2", "http/1.1"}
+	}
+
+	if flags.CACert != "" {
+		pem, err := os.ReadFile(flags.CACert)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA cert: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("failed to parse CA cert %q", flags.CACert)
+		}
+		config.RootCAs = pool
+	}
+
+	if flags.ClientCert != "" || flags.ClientKey != "" {
+		cert, err := tls.LoadX509KeyPair(flags.ClientCert, flags.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	return tls.Dial("tcp", net.JoinHostPort(host, port), config)
+}
@@ -0,0 +1,293 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultIdleTimeout is used when Transport.IdleTimeout is zero.
+const defaultIdleTimeout = 90 * time.Second
+
+// Request describes an outgoing HTTP request for use with Transport.
+type Request struct {
+	Method  string
+	URL     string
+	Headers []string
+	Body    io.Reader
+	Flags   *HttpFlags
+}
+
+// Response is the result of a RoundTrip.
+type Response struct {
+	StatusLine string
+	Headers    string
+	Body       []byte
+	Trailer    map[string]string
+}
+
+type idleConn struct {
+	conn     net.Conn
+	lastUsed time.Time
+}
+
+// Transport maintains a pool of idle connections keyed by
+// "scheme://host:port" and reuses them across requests instead of dialing
+// a fresh socket for every call to RoundTrip.
+type Transport struct {
+	// IdleTimeout controls how long a connection may sit idle in the pool
+	// before it is closed and evicted. Defaults to 90 seconds.
+	IdleTimeout time.Duration
+
+	mu        sync.Mutex
+	idle      map[string][]*idleConn
+	reaperOne sync.Once
+	done      chan struct{}
+}
+
+func connKey(scheme, host, port string) string {
+	return scheme + "://" + host + ":" + port
+}
+
+// RoundTrip sends req and returns its response, reusing a pooled connection
+// when one is available for the request's host and dialing a new one
+// otherwise. The connection is returned to the idle pool on success and
+// closed on failure.
+func (t *Transport) RoundTrip(req *Request) (*Response, error) {
+	scheme, host, port, path, err := parseURL(req.URL)
+	if err != nil {
+		return nil, err
+	}
+	key := connKey(scheme, host, port)
+
+	conn := t.getIdleConn(key)
+	if conn == nil {
+		conn, err = dial(scheme, host, port, req.Flags)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	resp, err := sendRequest(conn, host, path, req)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	t.putIdleConn(key, conn)
+	return resp, nil
+}
+
+// Close stops the idle-connection reaper and closes every pooled connection.
+func (t *Transport) Close() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.done != nil {
+		close(t.done)
+	}
+	for key, conns := range t.idle {
+		for _, c := range conns {
+			c.conn.Close()
+		}
+		delete(t.idle, key)
+	}
+}
+
+func (t *Transport) idleTimeout() time.Duration {
+	if t.IdleTimeout > 0 {
+		return t.IdleTimeout
+	}
+	return defaultIdleTimeout
+}
+
+func (t *Transport) getIdleConn(key string) net.Conn {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	conns := t.idle[key]
+	if len(conns) == 0 {
+		return nil
+	}
+	last := conns[len(conns)-1]
+	t.idle[key] = conns[:len(conns)-1]
+	return last.conn
+}
+
+func (t *Transport) putIdleConn(key string, conn net.Conn) {
+	t.startReaper()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.idle == nil {
+		t.idle = make(map[string][]*idleConn)
+	}
+	t.idle[key] = append(t.idle[key], &idleConn{conn: conn, lastUsed: time.Now()})
+}
+
+func (t *Transport) startReaper() {
+	t.reaperOne.Do(func() {
+		t.done = make(chan struct{})
+		go t.reapLoop()
+	})
+}
+
+func (t *Transport) reapLoop() {
+	ticker := time.NewTicker(t.idleTimeout() / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			t.reapExpired()
+		case <-t.done:
+			return
+		}
+	}
+}
+
+func (t *Transport) reapExpired() {
+	cutoff := time.Now().Add(-t.idleTimeout())
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for key, conns := range t.idle {
+		fresh := conns[:0]
+		for _, c := range conns {
+			if c.lastUsed.Before(cutoff) {
+				c.conn.Close()
+			} else {
+				fresh = append(fresh, c)
+			}
+		}
+		if len(fresh) == 0 {
+			delete(t.idle, key)
+		} else {
+			t.idle[key] = fresh
+		}
+	}
+}
+
+// sendRequest writes req over conn and reads back a framed response,
+// consuming exactly Content-Length bytes (or a full chunked body) so the
+// connection remains usable for a subsequent request.
+func sendRequest(conn net.Conn, host, path string, req *Request) (*Response, error) {
+	var bodyBytes []byte
+	var err error
+	if req.Body != nil {
+		bodyBytes, err = io.ReadAll(req.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read request body: %w", err)
+		}
+	}
+
+	chunkedBody := len(bodyBytes) > 0 && hasChunkedTransferEncoding(req.Headers)
+	head := buildRequestHead(req.Method, host, path, req.Headers, len(bodyBytes), chunkedBody, "keep-alive")
+	if _, err := conn.Write([]byte(head)); err != nil {
+		return nil, fmt.Errorf("failed to write request: %w", err)
+	}
+	if err := writeRequestBody(conn, bodyBytes, chunkedBody); err != nil {
+		return nil, err
+	}
+
+	reader := bufio.NewReader(conn)
+	headers, isChunked, err := readHeaders(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	var body bytes.Buffer
+	trailer, err := readResponseBody(reader, headers, isChunked, &body)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Response{
+		StatusLine: statusLine(headers),
+		Headers:    headers,
+		Body:       body.Bytes(),
+		Trailer:    trailer,
+	}, nil
+}
+
+// buildRequestHead formats the request line and header block for an
+// HTTP/1.1 request, adding a Content-Length for a non-chunked body and the
+// given Connection header. Shared by sendRequest and doHTTP so the two
+// callers can't drift on how a request is framed on the wire.
+func buildRequestHead(method, host, path string, headers []string, bodyLen int, chunkedBody bool, connection string) string {
+	requestLine := fmt.Sprintf("%s %s HTTP/1.1\r\n", method, path)
+	requestHeaders := fmt.Sprintf("Host: %s\r\n", host)
+	for _, header := range headers {
+		requestHeaders += header + "\r\n"
+	}
+	if bodyLen > 0 && !chunkedBody {
+		requestHeaders += fmt.Sprintf("Content-Length: %d\r\n", bodyLen)
+	}
+	requestHeaders += "Connection: " + connection + "\r\n\r\n"
+	return requestLine + requestHeaders
+}
+
+// writeRequestBody writes bodyBytes to conn, chunk-framing it via a
+// ChunkedWriter when chunked is set.
+func writeRequestBody(conn net.Conn, bodyBytes []byte, chunked bool) error {
+	if len(bodyBytes) == 0 {
+		return nil
+	}
+	if chunked {
+		cw := NewChunkedWriter(conn)
+		if _, err := cw.Write(bodyBytes); err != nil {
+			return fmt.Errorf("failed to write chunked request body: %w", err)
+		}
+		if err := cw.Close(); err != nil {
+			return fmt.Errorf("failed to finalize chunked request body: %w", err)
+		}
+		return nil
+	}
+	if _, err := conn.Write(bodyBytes); err != nil {
+		return fmt.Errorf("failed to write request body: %w", err)
+	}
+	return nil
+}
+
+// readResponseBody frames a response body read from reader according to
+// headers (chunked, Content-Length, or read-until-EOF) and copies the
+// decoded bytes to output, returning any chunked trailer headers.
+func readResponseBody(reader *bufio.Reader, headers string, isChunked bool, output io.Writer) (map[string]string, error) {
+	if isChunked {
+		return readChunkedResponse(reader, output)
+	}
+	if length, ok := parseContentLength(headers); ok {
+		if _, err := io.CopyN(output, reader, length); err != nil {
+			return nil, fmt.Errorf("failed to read response body: %w", err)
+		}
+		return nil, nil
+	}
+	readNonChunkedResponse(reader, output)
+	return nil, nil
+}
+
+// parseContentLength extracts the Content-Length header value from a raw
+// header block, returning ok=false when the header is absent or malformed.
+func parseContentLength(headers string) (int64, bool) {
+	const prefix = "content-length:"
+	for _, line := range strings.Split(headers, "\r\n") {
+		if len(line) <= len(prefix) || !strings.EqualFold(line[:len(prefix)], prefix) {
+			continue
+		}
+		n, err := strconv.ParseInt(strings.TrimSpace(line[len(prefix):]), 10, 64)
+		if err != nil {
+			return 0, false
+		}
+		return n, true
+	}
+	return 0, false
+}
+
+// statusLine returns the first line of a raw header block.
+func statusLine(headers string) string {
+	if idx := strings.Index(headers, "\r\n"); idx != -1 {
+		return headers[:idx]
+	}
+	return headers
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func runTestServerWithConnCounter(t *testing.T, mux *http.ServeMux, addr string) (*int32, func()) {
+	var connCount int32
+	server := &http.Server{
+		Addr:    addr,
+		Handler: mux,
+		ConnState: func(conn net.Conn, state http.ConnState) {
+			if state == http.StateNew {
+				atomic.AddInt32(&connCount, 1)
+			}
+		},
+	}
+	go server.ListenAndServe()
+	time.Sleep(5 * time.Millisecond)
+
+	return &connCount, func() { server.Close() }
+}
+
+func TestTransportReusesConnection(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "ok")
+	})
+
+	connCount, cleanup := runTestServerWithConnCounter(t, mux, "127.0.0.1:8085")
+	defer cleanup()
+
+	transport := &Transport{}
+	defer transport.Close()
+
+	for i := 0; i < 3; i++ {
+		resp, err := transport.RoundTrip(&Request{
+			Method: "GET",
+			URL:    "http://127.0.0.1:8085/",
+			Flags:  &HttpFlags{},
+		})
+		if err != nil {
+			t.Fatalf("RoundTrip %d failed: %v", i, err)
+		}
+		if string(resp.Body) != "ok" {
+			t.Fatalf("expected body %q, got %q", "ok", string(resp.Body))
+		}
+	}
+
+	if got := atomic.LoadInt32(connCount); got != 1 {
+		t.Fatalf("expected 1 underlying connection to be opened, got %d", got)
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// parseURL splits rawURL into its scheme, host, port, and path components.
+// Only the http and https schemes are supported. The host may be an IPv6
+// literal wrapped in brackets (e.g. "https://[::1]:8443/path"). The port
+// defaults to 443 for https and 80 for http when not explicitly given.
+func parseURL(rawURL string) (scheme, host, port, path string, err error) {
+	const schemeSep = "://"
+
+	idx := strings.Index(rawURL, schemeSep)
+	if idx == -1 {
+		return "", "", "", "", fmt.Errorf("invalid URL %q: missing scheme", rawURL)
+	}
+	scheme = rawURL[:idx]
+	if scheme != "http" && scheme != "https" {
+		return "", "", "", "", fmt.Errorf("invalid URL %q: unsupported scheme %q", rawURL, scheme)
+	}
+
+	rest := rawURL[idx+len(schemeSep):]
+	if rest == "" {
+		return "", "", "", "", fmt.Errorf("invalid URL %q: missing host", rawURL)
+	}
+
+	hostPort := rest
+	path = "/"
+	if slash := strings.IndexByte(rest, '/'); slash != -1 {
+		hostPort = rest[:slash]
+		path = rest[slash:]
+	}
+	if hostPort == "" {
+		return "", "", "", "", fmt.Errorf("invalid URL %q: missing host", rawURL)
+	}
+
+	host, port, err = splitHostPort(hostPort)
+	if err != nil {
+		return "", "", "", "", fmt.Errorf("invalid URL %q: %w", rawURL, err)
+	}
+	if port == "" {
+		if scheme == "https" {
+			port = "443"
+		} else {
+			port = "80"
+		}
+	}
+
+	return scheme, host, port, path, nil
+}
+
+// splitHostPort separates a "host:port" pair, accepting IPv6 literals
+// wrapped in brackets (e.g. "[::1]:8080"). The returned port is empty when
+// none was specified.
+func splitHostPort(hostPort string) (host, port string, err error) {
+	if strings.HasPrefix(hostPort, "[") {
+		end := strings.IndexByte(hostPort, ']')
+		if end == -1 {
+			return "", "", fmt.Errorf("missing closing ']' in IPv6 address %q", hostPort)
+		}
+		host = hostPort[1:end]
+		if host == "" {
+			return "", "", fmt.Errorf("empty IPv6 address in %q", hostPort)
+		}
+
+		remainder := hostPort[end+1:]
+		if remainder == "" {
+			return host, "", nil
+		}
+		if !strings.HasPrefix(remainder, ":") {
+			return "", "", fmt.Errorf("unexpected characters after IPv6 address %q", hostPort)
+		}
+		port = remainder[1:]
+		if _, err := strconv.Atoi(port); err != nil {
+			return "", "", fmt.Errorf("invalid port %q", port)
+		}
+		return host, port, nil
+	}
+
+	if strings.Count(hostPort, ":") > 1 {
+		return "", "", fmt.Errorf("bare IPv6 address %q must be wrapped in brackets", hostPort)
+	}
+
+	parts := strings.SplitN(hostPort, ":", 2)
+	host = parts[0]
+	if host == "" {
+		return "", "", fmt.Errorf("missing host in %q", hostPort)
+	}
+	if len(parts) == 2 {
+		port = parts[1]
+		if _, err := strconv.Atoi(port); err != nil {
+			return "", "", fmt.Errorf("invalid port %q", port)
+		}
+	}
+	return host, port, nil
+}
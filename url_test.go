@@ -0,0 +1,49 @@
+package main
+
+import "testing"
+
+func TestParseURL(t *testing.T) {
+	tests := []struct {
+		url        string
+		wantScheme string
+		wantHost   string
+		wantPort   string
+		wantPath   string
+	}{
+		{"http://example.com/path", "http", "example.com", "80", "/path"},
+		{"https://example.com/path", "https", "example.com", "443", "/path"},
+		{"http://example.com:8080/path", "http", "example.com", "8080", "/path"},
+		{"https://example.com", "https", "example.com", "443", "/"},
+		{"https://[::1]:8443/path", "https", "::1", "8443", "/path"},
+		{"http://[::1]/path", "http", "::1", "80", "/path"},
+	}
+
+	for _, tt := range tests {
+		scheme, host, port, path, err := parseURL(tt.url)
+		if err != nil {
+			t.Fatalf("parseURL(%q) returned error: %v", tt.url, err)
+		}
+		if scheme != tt.wantScheme || host != tt.wantHost || port != tt.wantPort || path != tt.wantPath {
+			t.Errorf("parseURL(%q) = (%q, %q, %q, %q), want (%q, %q, %q, %q)",
+				tt.url, scheme, host, port, path, tt.wantScheme, tt.wantHost, tt.wantPort, tt.wantPath)
+		}
+	}
+}
+
+func TestParseURLInvalid(t *testing.T) {
+	invalidURLs := []string{
+		"",
+		"example.com/path",
+		"ftp://example.com/path",
+		"http://",
+		"http://[::1/path",
+		"http://host:notaport/path",
+		"http://1:2:3/path",
+	}
+
+	for _, url := range invalidURLs {
+		if _, _, _, _, err := parseURL(url); err == nil {
+			t.Errorf("parseURL(%q) expected error, got nil", url)
+		}
+	}
+}